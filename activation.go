@@ -0,0 +1,52 @@
+package neat
+
+import "math"
+
+// ActivationFunc is a named activation function together with its
+// derivative. The derivative is required by (*NeuralNetwork).Backpropagate,
+// which needs it to compute per-neuron deltas; it's expressed in terms of
+// the function's own output (e.g. sigmoid'(x) = sigmoid(x)*(1-sigmoid(x))),
+// matching the convention of reusing the forward-pass signal already stored
+// on each Neuron.
+type ActivationFunc struct {
+	Name  string               // name, used to look the function up again after JSON decoding
+	Fn    func(float64) float64 // the activation function itself
+	Deriv func(float64) float64 // its derivative, taking the function's own output as input
+}
+
+// Predefined activation functions available to node genes.
+var (
+	Sigmoid = &ActivationFunc{
+		Name: "sigmoid",
+		Fn: func(x float64) float64 {
+			return 1.0 / (1.0 + math.Exp(-x))
+		},
+		Deriv: func(y float64) float64 {
+			return y * (1 - y)
+		},
+	}
+
+	Tanh = &ActivationFunc{
+		Name: "tanh",
+		Fn:   math.Tanh,
+		Deriv: func(y float64) float64 {
+			return 1 - y*y
+		},
+	}
+
+	ReLU = &ActivationFunc{
+		Name: "relu",
+		Fn: func(x float64) float64 {
+			if x < 0 {
+				return 0
+			}
+			return x
+		},
+		Deriv: func(y float64) float64 {
+			if y <= 0 {
+				return 0
+			}
+			return 1
+		},
+	}
+)
@@ -0,0 +1,189 @@
+package neat
+
+import "fmt"
+
+// Sample is a single labeled training example for Backpropagate.
+type Sample struct {
+	Inputs  []float64 // network inputs
+	Targets []float64 // desired outputs, aligned with the network's output neurons
+}
+
+// Backpropagate fine-tunes the weights of n's synapses with epochs passes of
+// gradient descent over samples, using mean-squared-error loss and a fixed
+// learning rate lr. Per-neuron deltas are computed by walking neurons in
+// reverse topological order (Kahn's algorithm on the synapse graph), so it
+// only supports feedforward (acyclic) networks; call it on networks decoded
+// by NewNeuralNetwork before any recurrent encoding (e.g. LinearGenome) is
+// involved. It returns an error if n's synapse graph has a cycle.
+func (n *NeuralNetwork) Backpropagate(samples []Sample, lr float64, epochs int) error {
+	order, err := n.topologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, sample := range samples {
+			if err := n.backpropSample(order, sample, lr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// topologicalOrder returns the network's neurons ordered so that every
+// neuron appears before all neurons it feeds into (i.e. topological order
+// with respect to signal flow from inputs to outputs), using Kahn's
+// algorithm over the synapse graph. It errors if the graph has a cycle.
+func (n *NeuralNetwork) topologicalOrder() ([]*Neuron, error) {
+	// inDegree counts, for each neuron, how many neurons feed into it.
+	inDegree := make(map[*Neuron]int, len(n.Neurons))
+	dependents := make(map[*Neuron][]*Neuron, len(n.Neurons))
+	for _, neuron := range n.Neurons {
+		if _, ok := inDegree[neuron]; !ok {
+			inDegree[neuron] = 0
+		}
+		for source := range neuron.Synapses {
+			inDegree[neuron]++
+			dependents[source] = append(dependents[source], neuron)
+		}
+	}
+
+	queue := make([]*Neuron, 0, len(n.Neurons))
+	for _, neuron := range n.Neurons {
+		if inDegree[neuron] == 0 {
+			queue = append(queue, neuron)
+		}
+	}
+
+	order := make([]*Neuron, 0, len(n.Neurons))
+	for len(queue) > 0 {
+		neuron := queue[0]
+		queue = queue[1:]
+		order = append(order, neuron)
+
+		for _, dependent := range dependents[neuron] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(n.Neurons) {
+		return nil, fmt.Errorf("neat: Backpropagate: synapse graph has a cycle; decode a feedforward genome or use LinearGenome instead")
+	}
+	return order, nil
+}
+
+// backpropSample runs one forward pass and one backward pass of a single
+// sample, updating weights in place.
+func (n *NeuralNetwork) backpropSample(order []*Neuron, sample Sample, lr float64) error {
+	outputs, err := n.FeedForward(sample.Inputs)
+	if err != nil {
+		return err
+	}
+	if len(outputs) != len(sample.Targets) {
+		return fmt.Errorf("neat: Backpropagate: %d outputs != %d targets", len(outputs), len(sample.Targets))
+	}
+
+	// FeedForward resets Signal/Activated after it runs, so re-activate to
+	// recover each neuron's last output for the backward pass.
+	for i := 0; i < n.NumInputs; i++ {
+		n.Neurons[i].Signal = sample.Inputs[i]
+	}
+	for i := n.NumInputs; i < n.NumInputs+n.NumOutputs; i++ {
+		n.Neurons[i].Activate()
+	}
+
+	delta := make(map[*Neuron]float64, len(n.Neurons))
+	for i := n.NumInputs; i < n.NumInputs+n.NumOutputs; i++ {
+		neuron := n.Neurons[i]
+		target := sample.Targets[i-n.NumInputs]
+		delta[neuron] = (neuron.Signal - target) * neuron.Activation.Deriv(neuron.Signal)
+	}
+
+	// order is inputs-first topological order, so walking it backwards visits
+	// outputs first; by the time we reach a neuron, every neuron it feeds
+	// into (later in signal flow, so earlier in this backward walk) already
+	// has its delta computed.
+	for i := len(order) - 1; i >= 0; i-- {
+		neuron := order[i]
+		if _, isOutput := delta[neuron]; isOutput {
+			continue
+		}
+		if len(neuron.Synapses) == 0 {
+			continue // input neuron; no incoming weights to adjust
+		}
+
+		downstream := 0.0
+		for _, dependent := range n.Neurons {
+			if weight, connected := dependent.Synapses[neuron]; connected {
+				downstream += delta[dependent] * weight
+			}
+		}
+		delta[neuron] = downstream * neuron.Activation.Deriv(neuron.Signal)
+	}
+
+	for neuron, d := range delta {
+		for source, weight := range neuron.Synapses {
+			neuron.Synapses[source] = weight - lr*d*source.Signal
+		}
+	}
+
+	// reset for the next FeedForward call.
+	for _, neuron := range n.Neurons {
+		neuron.Activated = false
+		neuron.Signal = 0.0
+	}
+	return nil
+}
+
+// syncWeights copies the tuned weights of n's synapses back into the
+// ConnGenes of the genome it was decoded from, by matching neurons and
+// connections by node ID. This is what makes a round of Backpropagate
+// Lamarckian: the tuning survives into the genome that gets inherited,
+// instead of being thrown away with the phenotype.
+func (n *NeuralNetwork) syncWeights(g *Genome) {
+	byID := make(map[int]*Neuron, len(n.Neurons))
+	for _, neuron := range n.Neurons {
+		byID[neuron.ID] = neuron
+	}
+
+	for _, connGene := range g.ConnGenes {
+		if connGene.Disabled {
+			continue
+		}
+		to, toOK := byID[connGene.To.ID]
+		from, fromOK := byID[connGene.From.ID]
+		if !toOK || !fromOK {
+			continue
+		}
+		if weight, ok := to.Synapses[from]; ok {
+			connGene.Weight = weight
+		}
+	}
+}
+
+// LamarckianEvaluation decodes genome, fine-tunes its phenotype's weights
+// with n.Config.LamarckianEpochs passes of backpropagation against
+// n.TrainingSet at learning rate n.Config.BackpropLR, writes the tuned
+// weights back into genome's ConnGenes so the improvement is inherited by
+// its offspring, then scores fitness with n.Evaluation as usual. If
+// n.Config.LamarckianEpochs is zero, or backpropagation fails (e.g. the
+// genome's synapse graph has a cycle), it falls back to scoring the
+// untouched phenotype.
+func (n *NEAT) LamarckianEvaluation(genome *Genome) float64 {
+	nn := NewNeuralNetwork(genome)
+
+	if n.Config.LamarckianEpochs > 0 && len(n.TrainingSet) > 0 {
+		if err := nn.Backpropagate(n.TrainingSet, n.Config.BackpropLR, n.Config.LamarckianEpochs); err == nil {
+			nn.syncWeights(genome)
+		}
+		// decode again: on success this picks up the synced weights, on
+		// failure it discards any partial tuning done before the error.
+		nn = NewNeuralNetwork(genome)
+	}
+
+	return n.Evaluation(nn)
+}
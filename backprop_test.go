@@ -0,0 +1,59 @@
+package neat
+
+import "testing"
+
+func TestBackpropagateReducesLoss(t *testing.T) {
+	in := &NodeGene{ID: 0, Type: "input"}
+	out := &NodeGene{ID: 1, Type: "output", Activation: Sigmoid}
+
+	g := &Genome{
+		NodeGenes: []*NodeGene{in, out},
+		ConnGenes: []*ConnGene{
+			{From: in, To: out, Weight: 0.1, Innovation: 0},
+		},
+	}
+
+	samples := []Sample{
+		{Inputs: []float64{1}, Targets: []float64{0.9}},
+	}
+
+	loss := func() float64 {
+		nn := NewNeuralNetwork(g)
+		outputs, err := nn.FeedForward(samples[0].Inputs)
+		if err != nil {
+			t.Fatalf("FeedForward() error = %v", err)
+		}
+		d := outputs[0] - samples[0].Targets[0]
+		return d * d
+	}
+
+	before := loss()
+
+	nn := NewNeuralNetwork(g)
+	if err := nn.Backpropagate(samples, 0.5, 200); err != nil {
+		t.Fatalf("Backpropagate() error = %v", err)
+	}
+	nn.syncWeights(g)
+
+	after := loss()
+	if after >= before {
+		t.Fatalf("loss did not decrease: before = %.6f, after = %.6f", before, after)
+	}
+}
+
+func TestBackpropagateDetectsCycle(t *testing.T) {
+	a := &NodeGene{ID: 0, Type: "hidden", Activation: Sigmoid}
+	b := &NodeGene{ID: 1, Type: "hidden", Activation: Sigmoid}
+
+	nn := &NeuralNetwork{
+		NumInputs:  0,
+		NumOutputs: 0,
+		Neurons:    []*Neuron{NewNeuron(a), NewNeuron(b)},
+	}
+	nn.Neurons[0].Synapses[nn.Neurons[1]] = 1.0
+	nn.Neurons[1].Synapses[nn.Neurons[0]] = 1.0
+
+	if _, err := nn.topologicalOrder(); err == nil {
+		t.Fatal("topologicalOrder() on a cyclic graph: want error, got nil")
+	}
+}
@@ -0,0 +1,243 @@
+package neat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// nodeGeneJSON is the wire format for NodeGene.
+type nodeGeneJSON struct {
+	ID             int    `json:"id"`
+	Type           string `json:"type"`
+	ActivationName string `json:"activation,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ng *NodeGene) MarshalJSON() ([]byte, error) {
+	alias := nodeGeneJSON{ID: ng.ID, Type: ng.Type}
+	if ng.Activation != nil {
+		alias.ActivationName = ng.Activation.Name
+	}
+	return json.Marshal(alias)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ng *NodeGene) UnmarshalJSON(data []byte) error {
+	var alias nodeGeneJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	ng.ID, ng.Type = alias.ID, alias.Type
+	if alias.ActivationName == "" {
+		return nil
+	}
+	activation, ok := activationByName[alias.ActivationName]
+	if !ok {
+		return fmt.Errorf("neat: unknown activation function %q", alias.ActivationName)
+	}
+	ng.Activation = activation
+	return nil
+}
+
+// connGeneJSON is the wire format for ConnGene: From/To refer to nodes by ID
+// rather than pointer, so that decoding doesn't depend on object identity.
+type connGeneJSON struct {
+	From       int     `json:"from"`
+	To         int     `json:"to"`
+	Weight     float64 `json:"weight"`
+	Disabled   bool    `json:"disabled,omitempty"`
+	Innovation int     `json:"innovation"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (cg *ConnGene) MarshalJSON() ([]byte, error) {
+	return json.Marshal(connGeneJSON{
+		From:       cg.From.ID,
+		To:         cg.To.ID,
+		Weight:     cg.Weight,
+		Disabled:   cg.Disabled,
+		Innovation: cg.Innovation,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. From/To are populated with
+// placeholder NodeGenes holding only an ID; Genome.UnmarshalJSON resolves
+// them to the genome's actual NodeGene pointers once all of them are known,
+// so that node objects referenced from several ConnGenes round-trip as the
+// same pointer rather than as separate copies.
+func (cg *ConnGene) UnmarshalJSON(data []byte) error {
+	var alias connGeneJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	cg.Weight, cg.Disabled, cg.Innovation = alias.Weight, alias.Disabled, alias.Innovation
+	cg.From = &NodeGene{ID: alias.From}
+	cg.To = &NodeGene{ID: alias.To}
+	return nil
+}
+
+// genomeJSON is the wire format for Genome.
+type genomeJSON struct {
+	ID        int         `json:"id"`
+	Fitness   float64     `json:"fitness"`
+	NodeGenes []*NodeGene `json:"nodeGenes"`
+	ConnGenes []*ConnGene `json:"connGenes"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g *Genome) MarshalJSON() ([]byte, error) {
+	return json.Marshal(genomeJSON{
+		ID:        g.ID,
+		Fitness:   g.Fitness,
+		NodeGenes: g.NodeGenes,
+		ConnGenes: g.ConnGenes,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *Genome) UnmarshalJSON(data []byte) error {
+	var alias genomeJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	g.ID, g.Fitness = alias.ID, alias.Fitness
+	g.NodeGenes, g.ConnGenes = alias.NodeGenes, alias.ConnGenes
+
+	byID := make(map[int]*NodeGene, len(g.NodeGenes))
+	for _, node := range g.NodeGenes {
+		byID[node.ID] = node
+	}
+	for _, conn := range g.ConnGenes {
+		if from, ok := byID[conn.From.ID]; ok {
+			conn.From = from
+		}
+		if to, ok := byID[conn.To.ID]; ok {
+			conn.To = to
+		}
+	}
+	return nil
+}
+
+// speciesJSON is the wire format for Species. Representative and Members are
+// serialized in full rather than by genome ID: SaveCheckpoint runs right
+// after RunFor, at which point they're the prior generation's genomes from
+// the last speciation round, which inheritParallel has already replaced with
+// a new Population — so a genome-ID reference into Population wouldn't
+// resolve to anything but an empty stub.
+type speciesJSON struct {
+	ID             int       `json:"id"`
+	Representative *Genome   `json:"representative"`
+	Members        []*Genome `json:"members"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *Species) MarshalJSON() ([]byte, error) {
+	return json.Marshal(speciesJSON{
+		ID:             s.ID,
+		Representative: s.Representative,
+		Members:        s.Members,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Species) UnmarshalJSON(data []byte) error {
+	var alias speciesJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	s.ID = alias.ID
+	s.Representative = alias.Representative
+	s.Members = alias.Members
+	return nil
+}
+
+// neatJSON is the wire format for NEAT.
+type neatJSON struct {
+	Config            *Config    `json:"config"`
+	Population        []*Genome  `json:"population"`
+	Species           []*Species `json:"species"`
+	Best              *Genome    `json:"best,omitempty"` // in full, like speciesJSON's genomes: it may no longer be in Population
+	NextGenomeID      int        `json:"nextGenomeId"`
+	NextSpeciesID     int        `json:"nextSpeciesId"`
+	NextNodeID        int        `json:"nextNodeId"`
+	NextInnovation    int        `json:"nextInnovation"`
+	DistanceThreshold float64    `json:"distanceThreshold"`
+	RNGSeed           int64      `json:"rngSeed"`
+	Generation        int        `json:"generation"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n *NEAT) MarshalJSON() ([]byte, error) {
+	alias := neatJSON{
+		Config:            n.Config,
+		Population:        n.Population,
+		Species:           n.Species,
+		Best:              n.Best,
+		NextGenomeID:      n.nextGenomeID,
+		NextSpeciesID:     n.nextSpeciesID,
+		NextNodeID:        n.nextNodeID,
+		NextInnovation:    n.nextInnovation,
+		DistanceThreshold: n.distanceThreshold,
+		RNGSeed:           n.rngSeed,
+		Generation:        n.generation,
+	}
+	return json.Marshal(alias)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NEAT) UnmarshalJSON(data []byte) error {
+	var alias neatJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	n.Config = alias.Config
+	n.Population = alias.Population
+	n.Species = alias.Species
+	n.Best = alias.Best
+	n.nextGenomeID = alias.NextGenomeID
+	n.nextSpeciesID = alias.NextSpeciesID
+	n.nextNodeID = alias.NextNodeID
+	n.nextInnovation = alias.NextInnovation
+	n.distanceThreshold = alias.DistanceThreshold
+	n.rngSeed = alias.RNGSeed
+	n.generation = alias.Generation
+	n.innovations = make(map[[2]int]int)
+	return nil
+}
+
+// SaveCheckpoint serializes the entire evolutionary state — population,
+// species, the best genome found so far, ID/innovation counters, and the
+// configuration — to a JSON file at path, so that a run can be resumed
+// later with LoadCheckpoint.
+func (n *NEAT) SaveCheckpoint(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(n)
+}
+
+// LoadCheckpoint restores a NEAT instance previously written by
+// SaveCheckpoint. Functions cannot be serialized to JSON, so the returned
+// NEAT has a nil Evaluation; the caller must assign one before calling Run,
+// RunFor or evaluateParallel.
+func LoadCheckpoint(path string) (*NEAT, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	n := &NEAT{}
+	decoder := json.NewDecoder(f)
+	if err := decoder.Decode(n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
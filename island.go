@@ -0,0 +1,234 @@
+package neat
+
+import (
+	"sort"
+	"sync"
+)
+
+// MigrationTopology selects which islands exchange migrants in IslandNEAT.
+type MigrationTopology int
+
+// Supported migration topologies.
+const (
+	// MigrationRing migrates between each island and its two neighbors in a
+	// ring (island i <-> islands i-1 and i+1, wrapping around).
+	MigrationRing MigrationTopology = iota
+	// MigrationFullyConnected migrates between every pair of islands.
+	MigrationFullyConnected
+	// MigrationCustom migrates according to IslandNEAT.Adjacency.
+	MigrationCustom
+)
+
+// IslandNEAT runs NumIslands independent NEAT populations in parallel,
+// each with its own species list and innovation-number namespace, and
+// periodically migrates top performers between islands. This preserves
+// genetic diversity across the overall population in a way a single,
+// all-at-once NEAT.Run can't, since evaluateParallel there treats the
+// population as one undifferentiated pool.
+type IslandNEAT struct {
+	Config    *Config // shared hyperparameters for every island
+	Islands   []*NEAT // the independent island populations
+	Adjacency [][]int // neighbor island indices, used only for MigrationCustom
+}
+
+// NewIslandNEAT creates an IslandNEAT with Config.NumIslands independent
+// NEAT instances, each seeded with its own random initial population.
+// adjacency is only consulted when Config.MigrationTopology is
+// MigrationCustom; pass nil otherwise.
+func NewIslandNEAT(config *Config, evaluation EvaluationFunc, adjacency [][]int) *IslandNEAT {
+	islands := make([]*NEAT, config.NumIslands)
+	for i := range islands {
+		islands[i] = New(config, evaluation)
+	}
+	return &IslandNEAT{Config: config, Islands: islands, Adjacency: adjacency}
+}
+
+// Run evolves every island in parallel for Config.NumGenerations
+// generations in total, pausing every Config.MigrationInterval generations
+// to migrate Config.MigrationSize top genomes from each island to its
+// neighbors.
+func (isl *IslandNEAT) Run() {
+	interval := isl.Config.MigrationInterval
+	if interval <= 0 {
+		interval = isl.Config.NumGenerations
+	}
+
+	for done := 0; done < isl.Config.NumGenerations; done += interval {
+		epoch := interval
+		if done+epoch > isl.Config.NumGenerations {
+			epoch = isl.Config.NumGenerations - done
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(len(isl.Islands))
+		for _, island := range isl.Islands {
+			go func(island *NEAT) {
+				defer wg.Done()
+				island.RunFor(epoch)
+			}(island)
+		}
+		wg.Wait()
+
+		isl.migrate()
+	}
+}
+
+// Best returns the best genome found across every island.
+func (isl *IslandNEAT) Best() *Genome {
+	var best *Genome
+	for _, island := range isl.Islands {
+		if island.Best == nil {
+			continue
+		}
+		if best == nil ||
+			(isl.Config.MinimizeFitness && island.Best.Fitness < best.Fitness) ||
+			(!isl.Config.MinimizeFitness && island.Best.Fitness > best.Fitness) {
+			best = island.Best
+		}
+	}
+	return best
+}
+
+// neighbors returns the indices of the islands that island i migrates with,
+// according to Config.MigrationTopology.
+func (isl *IslandNEAT) neighbors(i int) []int {
+	m := len(isl.Islands)
+	switch isl.Config.MigrationTopology {
+	case MigrationFullyConnected:
+		neighbors := make([]int, 0, m-1)
+		for j := 0; j < m; j++ {
+			if j != i {
+				neighbors = append(neighbors, j)
+			}
+		}
+		return neighbors
+	case MigrationCustom:
+		if i < len(isl.Adjacency) {
+			return isl.Adjacency[i]
+		}
+		return nil
+	default: // MigrationRing
+		if m < 2 {
+			return nil
+		}
+		return []int{(i - 1 + m) % m, (i + 1) % m}
+	}
+}
+
+// topGenomes returns up to k of island's fittest genomes, fittest first.
+func topGenomes(island *NEAT, k int) []*Genome {
+	sorted := append([]*Genome(nil), island.Population...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if island.Config.MinimizeFitness {
+			return sorted[i].Fitness < sorted[j].Fitness
+		}
+		return sorted[i].Fitness > sorted[j].Fitness
+	})
+	if k > len(sorted) {
+		k = len(sorted)
+	}
+	return sorted[:k]
+}
+
+// migrate copies each island's top Config.MigrationSize genomes into its
+// neighbors' populations (remapping innovation numbers along the way via
+// migrateGenome), then culls each island's population back down to
+// Config.PopulationSize by dropping its weakest members. migrate runs right
+// after RunFor, when the population is the freshly bred, not-yet-evaluated
+// offspring from inheritParallel, so it evaluates each island first — without
+// that, selection and culling would rank on stale zero-value Fitness instead
+// of how the offspring actually perform.
+func (isl *IslandNEAT) migrate() {
+	if isl.Config.MigrationSize <= 0 {
+		return
+	}
+
+	for _, island := range isl.Islands {
+		island.evaluateParallel()
+	}
+
+	emigrants := make([][]*Genome, len(isl.Islands))
+	for i, island := range isl.Islands {
+		emigrants[i] = topGenomes(island, isl.Config.MigrationSize)
+	}
+
+	for i := range isl.Islands {
+		for _, j := range isl.neighbors(i) {
+			dest := isl.Islands[j]
+			for _, genome := range emigrants[i] {
+				dest.Population = append(dest.Population, migrateGenome(genome, dest))
+			}
+		}
+	}
+
+	for _, island := range isl.Islands {
+		sort.Slice(island.Population, func(a, b int) bool {
+			if island.Config.MinimizeFitness {
+				return island.Population[a].Fitness < island.Population[b].Fitness
+			}
+			return island.Population[a].Fitness > island.Population[b].Fitness
+		})
+		if len(island.Population) > isl.Config.PopulationSize {
+			island.Population = island.Population[:isl.Config.PopulationSize]
+		}
+	}
+}
+
+// migrateGenome copies g into dest's innovation-number namespace: input and
+// output node IDs are assumed stable across islands, since every island
+// starts from the same Config, but hidden node IDs were allocated
+// independently per island and may collide by coincidence, so they're
+// remapped to freshly allocated IDs in dest. Connection genes are then
+// re-keyed by (from, to) against the connections dest's population already
+// carries, falling back to dest.innovationOf (which allocates a fresh
+// innovation number) for any connection dest's genomes don't have.
+func migrateGenome(g *Genome, dest *NEAT) *Genome {
+	known := make(map[[2]int]int)
+	for _, genome := range dest.Population {
+		for _, conn := range genome.ConnGenes {
+			known[[2]int{conn.From.ID, conn.To.ID}] = conn.Innovation
+		}
+	}
+
+	remap := make(map[int]int, len(g.NodeGenes))
+	nodeGenes := make([]*NodeGene, len(g.NodeGenes))
+	byOldID := make(map[int]*NodeGene, len(g.NodeGenes))
+
+	for i, node := range g.NodeGenes {
+		id := node.ID
+		if node.Type == "hidden" {
+			newID, ok := remap[node.ID]
+			if !ok {
+				newID = dest.newNodeID()
+				remap[node.ID] = newID
+			}
+			id = newID
+		}
+		copied := &NodeGene{ID: id, Type: node.Type, Activation: node.Activation}
+		nodeGenes[i] = copied
+		byOldID[node.ID] = copied
+	}
+
+	connGenes := make([]*ConnGene, len(g.ConnGenes))
+	for i, conn := range g.ConnGenes {
+		from, to := byOldID[conn.From.ID], byOldID[conn.To.ID]
+		innovation, ok := known[[2]int{from.ID, to.ID}]
+		if !ok {
+			innovation = dest.innovationOf(from.ID, to.ID)
+		}
+		connGenes[i] = &ConnGene{
+			From:       from,
+			To:         to,
+			Weight:     conn.Weight,
+			Disabled:   conn.Disabled,
+			Innovation: innovation,
+		}
+	}
+
+	child := NewGenome(dest.nextGenomeID, 0, 0)
+	dest.nextGenomeID++
+	child.NodeGenes = nodeGenes
+	child.ConnGenes = connGenes
+	child.Fitness = g.Fitness
+	return child
+}
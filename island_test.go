@@ -0,0 +1,85 @@
+package neat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIslandNEATMigrationKeepsPopulationSizeConstant(t *testing.T) {
+	rand.Seed(3)
+
+	config := &Config{
+		NumInputs:         2,
+		NumOutputs:        1,
+		NumGenerations:    6,
+		PopulationSize:    16,
+		SurvivalRate:      0.5,
+		RatePerturb:       0.8,
+		RateAddNode:       0.03,
+		RateAddConn:       0.1,
+		DistanceThreshold: 3.0,
+		CoeffUnmatching:   1.0,
+		CoeffMatching:     0.4,
+		NumIslands:        3,
+		MigrationInterval: 2,
+		MigrationSize:     2,
+		MigrationTopology: MigrationRing,
+	}
+
+	isl := NewIslandNEAT(config, xorFitness, nil)
+
+	isl.Run()
+
+	for i, island := range isl.Islands {
+		if len(island.Population) != config.PopulationSize {
+			t.Fatalf("island %d: population size = %d, want %d", i, len(island.Population), config.PopulationSize)
+		}
+	}
+
+	if isl.Best() == nil {
+		t.Fatal("Best() = nil, want a champion genome")
+	}
+}
+
+// TestIslandNEATMigrateEvaluatesBeforeSelecting checks that migrate ranks
+// and culls on each island's actual fitness, not the zero-value Fitness
+// inheritParallel's freshly bred offspring start with.
+func TestIslandNEATMigrateEvaluatesBeforeSelecting(t *testing.T) {
+	rand.Seed(4)
+
+	config := &Config{
+		NumInputs:         2,
+		NumOutputs:        1,
+		PopulationSize:    16,
+		SurvivalRate:      0.5,
+		RatePerturb:       0.8,
+		RateAddNode:       0.03,
+		RateAddConn:       0.1,
+		DistanceThreshold: 3.0,
+		CoeffUnmatching:   1.0,
+		CoeffMatching:     0.4,
+		NumIslands:        2,
+		MigrationSize:     2,
+		MigrationTopology: MigrationRing,
+	}
+
+	isl := NewIslandNEAT(config, xorFitness, nil)
+	for _, island := range isl.Islands {
+		island.RunFor(1)
+	}
+
+	isl.migrate()
+
+	for i, island := range isl.Islands {
+		allZero := true
+		for _, genome := range island.Population {
+			if genome.Fitness != 0 {
+				allZero = false
+				break
+			}
+		}
+		if allZero {
+			t.Fatalf("island %d: every genome has Fitness == 0 after migrate, want evaluated fitness", i)
+		}
+	}
+}
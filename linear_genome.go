@@ -0,0 +1,316 @@
+package neat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// activationByName looks up one of the package's predefined activation
+// functions by its Name, for decoding JSON that can't carry a function value
+// directly.
+var activationByName = map[string]*ActivationFunc{
+	Sigmoid.Name: Sigmoid,
+	Tanh.Name:    Tanh,
+	ReLU.Name:    ReLU,
+}
+
+// LinearGeneType identifies the kind of a single LinearGene.
+type LinearGeneType int
+
+// The kinds of gene that can appear in a LinearGenome.
+const (
+	GeneInput LinearGeneType = iota
+	GeneNeuron
+	GeneForward
+	GeneRecurrent
+	GeneBias
+)
+
+// LinearGene is a single instruction of a LinearGenome's stack program. Which
+// fields are meaningful depends on Type:
+//   - GeneInput: Index is the input slot to read, Weight scales it. Each use
+//     of an input emits its own GeneInput, since re-reading an input is
+//     free — unlike a neuron's output there's nothing to avoid recomputing.
+//   - GeneNeuron: ID, Activation and Arity describe the neuron; the top
+//     Arity values on the stack are popped, summed, passed through
+//     Activation, then multiplied by Weight (always 1.0 as emitted by
+//     NewLinearGenome, so the recorded output is the neuron's raw,
+//     un-weighted activation) and pushed. The result is also recorded so
+//     that every GeneForward/GeneRecurrent referencing this neuron's ID can
+//     read it back, each applying its own connection's weight.
+//   - GeneForward: Source and Weight describe a weighted read of another
+//     neuron's output as computed earlier in this same evaluation pass. The
+//     referenced neuron's GeneNeuron must appear earlier (see NewLinearGenome).
+//   - GeneRecurrent: Source and Weight describe a weighted read of another
+//     neuron's output as it stood at the end of the previous evaluation
+//     pass, which is how a genuine cycle in the genome (the source is
+//     mid-evaluation, an ancestor of the neuron being defined) is broken
+//     without requiring the linear form itself to be acyclic.
+//   - GeneBias: Weight is pushed directly.
+type LinearGene struct {
+	Type       LinearGeneType  `json:"type"`
+	Index      int             `json:"index,omitempty"`  // GeneInput
+	ID         int             `json:"id,omitempty"`     // GeneNeuron
+	Activation *ActivationFunc `json:"-"`                // GeneNeuron
+	Arity      int             `json:"arity,omitempty"`  // GeneNeuron
+	Source     int             `json:"source,omitempty"` // GeneForward, GeneRecurrent
+	Weight     float64         `json:"weight,omitempty"` // GeneInput, GeneNeuron, GeneForward, GeneRecurrent, GeneBias
+}
+
+// LinearGenome is a CGE-style (Common Genetic Encoding) phenotype: a
+// left-to-right sequence of genes evaluated with a single stack pass, rather
+// than the recursive pointer-graph traversal NeuralNetwork uses. The
+// sequence is in postfix order — a gene that pushes a value always appears
+// before the gene(s) that consume it — so Evaluate is a single
+// left-to-right scan with no separate dependency resolution step. Because
+// each neuron's most recent output is cached, GeneRecurrent genes can read
+// it on the next pass without requiring the graph itself to be acyclic,
+// giving LinearGenome support for recurrent connections.
+type LinearGenome struct {
+	NumInputs  int          // number of inputs
+	NumOutputs int          // number of outputs
+	OutputIDs  []int        // node IDs of the output neurons, in declaration order
+	Genes      []LinearGene // postfix sequence of genes
+
+	outputs map[int]float64 // neuron ID -> output from the evaluation pass currently in progress
+	prev    map[int]float64 // neuron ID -> output from the previous evaluation pass
+}
+
+// NewLinearGenome decodes g into an equivalent LinearGenome. Each hidden or
+// output neuron's defining subgenome (its GeneNeuron plus the reference
+// genes for its own inputs) is emitted exactly once, the first time it's
+// reached in a depth-first walk from the output neurons; every use of that
+// neuron's value, including the one that triggered the walk into it, reads
+// it back through a GeneForward (or GeneRecurrent, if the edge closes a
+// cycle back to a neuron that's still being defined). This keeps each
+// neuron's Arity equal to exactly the number of incoming connections it has,
+// regardless of how many other neurons also consume its output.
+func NewLinearGenome(g *Genome) *LinearGenome {
+	byID := make(map[int]*NodeGene, len(g.NodeGenes))
+	incoming := make(map[int][]*ConnGene)
+	inputIndex := make(map[int]int)
+	numInputs, numOutputs := 0, 0
+	var outputIDs []int
+
+	for _, node := range g.NodeGenes {
+		byID[node.ID] = node
+		switch node.Type {
+		case "input":
+			inputIndex[node.ID] = numInputs
+			numInputs++
+		case "output":
+			outputIDs = append(outputIDs, node.ID)
+			numOutputs++
+		}
+	}
+	for _, conn := range g.ConnGenes {
+		if conn.Disabled {
+			continue
+		}
+		incoming[conn.To.ID] = append(incoming[conn.To.ID], conn)
+	}
+
+	lg := &LinearGenome{NumInputs: numInputs, NumOutputs: numOutputs, OutputIDs: outputIDs}
+
+	emitted := make(map[int]bool)
+	inProgress := make(map[int]bool)
+
+	var emit func(node *NodeGene)
+	emit = func(node *NodeGene) {
+		if emitted[node.ID] {
+			return
+		}
+		inProgress[node.ID] = true
+
+		refs := make([]LinearGene, 0, len(incoming[node.ID]))
+		for _, conn := range incoming[node.ID] {
+			source := byID[conn.From.ID]
+			switch {
+			case source.Type == "input":
+				refs = append(refs, LinearGene{Type: GeneInput, Index: inputIndex[source.ID], Weight: conn.Weight})
+			case inProgress[source.ID]:
+				refs = append(refs, LinearGene{Type: GeneRecurrent, Source: source.ID, Weight: conn.Weight})
+			default:
+				emit(source) // appends source's own definition now, before (to the left of) this reference
+				refs = append(refs, LinearGene{Type: GeneForward, Source: source.ID, Weight: conn.Weight})
+			}
+		}
+
+		lg.Genes = append(lg.Genes, refs...)
+		lg.Genes = append(lg.Genes, LinearGene{
+			Type:       GeneNeuron,
+			ID:         node.ID,
+			Activation: node.Activation,
+			Arity:      len(refs),
+			Weight:     1.0,
+		})
+
+		inProgress[node.ID] = false
+		emitted[node.ID] = true
+	}
+
+	for _, id := range outputIDs {
+		emit(byID[id])
+	}
+
+	return lg
+}
+
+// Evaluate runs the stack-based evaluation pass, scanning Genes left to
+// right, and returns the network's output values in the order the output
+// neurons were declared.
+func (lg *LinearGenome) Evaluate(inputs []float64) []float64 {
+	if lg.prev == nil {
+		lg.prev = make(map[int]float64)
+	}
+	lg.outputs = make(map[int]float64)
+
+	stack := make([]float64, 0, len(lg.Genes))
+	pop := func(n int) []float64 {
+		values := append([]float64(nil), stack[len(stack)-n:]...)
+		stack = stack[:len(stack)-n]
+		return values
+	}
+
+	for _, gene := range lg.Genes {
+		switch gene.Type {
+		case GeneInput:
+			stack = append(stack, inputs[gene.Index]*gene.Weight)
+		case GeneBias:
+			stack = append(stack, gene.Weight)
+		case GeneForward:
+			stack = append(stack, lg.outputs[gene.Source]*gene.Weight)
+		case GeneRecurrent:
+			stack = append(stack, lg.prev[gene.Source]*gene.Weight)
+		case GeneNeuron:
+			sum := 0.0
+			for _, v := range pop(gene.Arity) {
+				sum += v
+			}
+			out := gene.Activation.Fn(sum) * gene.Weight
+			lg.outputs[gene.ID] = out
+			stack = append(stack, out)
+		}
+	}
+
+	lg.prev = lg.outputs
+
+	results := make([]float64, 0, len(lg.OutputIDs))
+	for _, id := range lg.OutputIDs {
+		results = append(results, lg.outputs[id])
+	}
+	return results
+}
+
+// linearGeneJSON is the wire format for LinearGene: Activation is a function
+// value and can't be marshaled directly, so it's swapped for its registered
+// name.
+type linearGeneJSON struct {
+	Type           LinearGeneType `json:"type"`
+	Index          int            `json:"index,omitempty"`
+	ID             int            `json:"id,omitempty"`
+	ActivationName string         `json:"activation,omitempty"`
+	Arity          int            `json:"arity,omitempty"`
+	Source         int            `json:"source,omitempty"`
+	Weight         float64        `json:"weight,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g LinearGene) MarshalJSON() ([]byte, error) {
+	alias := linearGeneJSON{
+		Type:   g.Type,
+		Index:  g.Index,
+		ID:     g.ID,
+		Arity:  g.Arity,
+		Source: g.Source,
+		Weight: g.Weight,
+	}
+	if g.Activation != nil {
+		alias.ActivationName = g.Activation.Name
+	}
+	return json.Marshal(alias)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *LinearGene) UnmarshalJSON(data []byte) error {
+	var alias linearGeneJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	*g = LinearGene{
+		Type:   alias.Type,
+		Index:  alias.Index,
+		ID:     alias.ID,
+		Arity:  alias.Arity,
+		Source: alias.Source,
+		Weight: alias.Weight,
+	}
+	if alias.ActivationName != "" {
+		activation, ok := activationByName[alias.ActivationName]
+		if !ok {
+			return fmt.Errorf("neat: unknown activation function %q", alias.ActivationName)
+		}
+		g.Activation = activation
+	}
+	return nil
+}
+
+// linearGenomeJSON is the wire format for LinearGenome.
+type linearGenomeJSON struct {
+	NumInputs  int          `json:"numInputs"`
+	NumOutputs int          `json:"numOutputs"`
+	OutputIDs  []int        `json:"outputIds"`
+	Genes      []LinearGene `json:"genes"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (lg *LinearGenome) MarshalJSON() ([]byte, error) {
+	return json.Marshal(linearGenomeJSON{
+		NumInputs:  lg.NumInputs,
+		NumOutputs: lg.NumOutputs,
+		OutputIDs:  lg.OutputIDs,
+		Genes:      lg.Genes,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (lg *LinearGenome) UnmarshalJSON(data []byte) error {
+	var alias linearGenomeJSON
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	lg.NumInputs = alias.NumInputs
+	lg.NumOutputs = alias.NumOutputs
+	lg.OutputIDs = alias.OutputIDs
+	lg.Genes = alias.Genes
+	return nil
+}
+
+// mutateAddSubgenome inserts a new subgenome, the genes in inputs followed by
+// the GeneNeuron neuron they feed, starting at position i in Genes. The
+// invariant that a neuron's subgenome length equals its declared arity holds
+// only when len(inputs) equals neuron.Arity; this is not checked.
+func (lg *LinearGenome) mutateAddSubgenome(i int, neuron LinearGene, inputs []LinearGene) {
+	subgenome := append(append([]LinearGene(nil), inputs...), neuron)
+	genes := make([]LinearGene, 0, len(lg.Genes)+len(subgenome))
+	genes = append(genes, lg.Genes[:i]...)
+	genes = append(genes, subgenome...)
+	genes = append(genes, lg.Genes[i:]...)
+	lg.Genes = genes
+}
+
+// mutateRemoveSubgenome removes the subgenome of Arity+1 genes ending at
+// position i (the GeneNeuron gene itself plus the Arity genes that feed it),
+// preserving the subgenome-length invariant.
+func (lg *LinearGenome) mutateRemoveSubgenome(i int) {
+	if lg.Genes[i].Type != GeneNeuron {
+		return
+	}
+	arity := lg.Genes[i].Arity
+	start := i - arity
+	if start < 0 {
+		return
+	}
+	lg.Genes = append(lg.Genes[:start], lg.Genes[i+1:]...)
+}
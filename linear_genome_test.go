@@ -0,0 +1,109 @@
+package neat
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestLinearGenomeEvaluateMatchesFeedForward(t *testing.T) {
+	in1 := &NodeGene{ID: 0, Type: "input"}
+	in2 := &NodeGene{ID: 1, Type: "input"}
+	out := &NodeGene{ID: 2, Type: "output", Activation: Sigmoid}
+
+	g := &Genome{
+		NodeGenes: []*NodeGene{in1, in2, out},
+		ConnGenes: []*ConnGene{
+			{From: in1, To: out, Weight: 0.5, Innovation: 0},
+			{From: in2, To: out, Weight: -1.5, Innovation: 1},
+		},
+	}
+
+	nn := NewNeuralNetwork(g)
+	wantOutputs, err := nn.FeedForward([]float64{1, 1})
+	if err != nil {
+		t.Fatalf("FeedForward() error = %v", err)
+	}
+
+	lg := NewLinearGenome(g)
+	gotOutputs := lg.Evaluate([]float64{1, 1})
+
+	if len(gotOutputs) != 1 {
+		t.Fatalf("Evaluate() returned %d outputs, want 1", len(gotOutputs))
+	}
+	if math.Abs(gotOutputs[0]-wantOutputs[0]) > 1e-9 {
+		t.Fatalf("Evaluate() = %.6f, want %.6f", gotOutputs[0], wantOutputs[0])
+	}
+}
+
+func TestLinearGenomeEvaluateMatchesFeedForwardWithHiddenNode(t *testing.T) {
+	in1 := &NodeGene{ID: 0, Type: "input"}
+	in2 := &NodeGene{ID: 1, Type: "input"}
+	out := &NodeGene{ID: 2, Type: "output", Activation: Sigmoid}
+	hidden := &NodeGene{ID: 3, Type: "hidden", Activation: Tanh}
+
+	g := &Genome{
+		NodeGenes: []*NodeGene{in1, in2, out, hidden},
+		ConnGenes: []*ConnGene{
+			{From: in1, To: hidden, Weight: 0.6, Innovation: 0},
+			{From: in2, To: hidden, Weight: -0.3, Innovation: 1},
+			{From: hidden, To: out, Weight: 0.9, Innovation: 2},
+			{From: in1, To: out, Weight: 0.2, Innovation: 3},
+		},
+	}
+
+	nn := NewNeuralNetwork(g)
+	wantOutputs, err := nn.FeedForward([]float64{0.4, 0.7})
+	if err != nil {
+		t.Fatalf("FeedForward() error = %v", err)
+	}
+
+	lg := NewLinearGenome(g)
+	gotOutputs := lg.Evaluate([]float64{0.4, 0.7})
+
+	if len(gotOutputs) != 1 {
+		t.Fatalf("Evaluate() returned %d outputs, want 1", len(gotOutputs))
+	}
+	if math.Abs(gotOutputs[0]-wantOutputs[0]) > 1e-9 {
+		t.Fatalf("Evaluate() = %.6f, want %.6f", gotOutputs[0], wantOutputs[0])
+	}
+}
+
+func TestLinearGenomeJSONRoundTripEvaluates(t *testing.T) {
+	in1 := &NodeGene{ID: 0, Type: "input"}
+	in2 := &NodeGene{ID: 1, Type: "input"}
+	out := &NodeGene{ID: 2, Type: "output", Activation: Sigmoid}
+	hidden := &NodeGene{ID: 3, Type: "hidden", Activation: Tanh}
+
+	g := &Genome{
+		NodeGenes: []*NodeGene{in1, in2, out, hidden},
+		ConnGenes: []*ConnGene{
+			{From: in1, To: hidden, Weight: 0.6, Innovation: 0},
+			{From: in2, To: hidden, Weight: -0.3, Innovation: 1},
+			{From: hidden, To: out, Weight: 0.9, Innovation: 2},
+			{From: in1, To: out, Weight: 0.2, Innovation: 3},
+		},
+	}
+
+	want := NewLinearGenome(g).Evaluate([]float64{0.4, 0.7})
+
+	data, err := json.Marshal(NewLinearGenome(g))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded LinearGenome
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	got := decoded.Evaluate([]float64{0.4, 0.7})
+	if len(got) != len(want) {
+		t.Fatalf("Evaluate() returned %d outputs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("Evaluate()[%d] = %.6f, want %.6f", i, got[i], want[i])
+		}
+	}
+}
@@ -32,10 +32,21 @@ type Config struct {
 	RateAddNode float64 `json:"rateAddNode"` // mutation by adding a node
 	RateAddConn float64 `json:"rateAddConn"` // mutation by adding a connection
 
+	// Lamarckian backpropagation settings
+	LamarckianEpochs int     `json:"lamarckianEpochs"` // epochs of backprop run against TrainingSet before each genome is scored; 0 disables it
+	BackpropLR       float64 `json:"backpropLR"`       // learning rate used by LamarckianEpochs
+
+	// island model settings; only used via IslandNEAT, not plain NEAT
+	NumIslands        int               `json:"numIslands"`        // number of islands NewIslandNEAT creates; with 1 no migration happens, with 0 nothing is evolved
+	MigrationInterval int               `json:"migrationInterval"` // generations between migrations
+	MigrationSize     int               `json:"migrationSize"`     // number of top genomes migrated to each neighbor island
+	MigrationTopology MigrationTopology `json:"migrationTopology"` // which islands exchange migrants
+
 	// compatibility distance coefficient settings
 	DistanceThreshold float64 `json:"distanceThreshold"` // distance threshold
 	CoeffUnmatching   float64 `json:"coeffUnmatching"`   // unmatching genes
 	CoeffMatching     float64 `json:"coeffMatching"`     // matching genes
+	TargetSpecies     int     `json:"targetSpecies"`     // desired number of species; DistanceThreshold is adapted each generation to approach it. 0 disables adaptation
 }
 
 // NewConfig creates a new instance of Config, given the name of a JSON file
@@ -92,14 +103,63 @@ func (c *Config) Summarize() {
 
 // NEAT is the implementation of NeuroEvolution of Augmenting Topology (NEAT).
 type NEAT struct {
-	Config     *Config        // configuration
-	Population []*Genome      // population of genome
-	Species    []*Species     // subpopulations of genomes grouped by species
-	Evaluation EvaluationFunc // evaluation function
-	Best       *Genome        // best performing genome
+	Config      *Config        // configuration
+	Population  []*Genome      // population of genome
+	Species     []*Species     // subpopulations of genomes grouped by species
+	Evaluation  EvaluationFunc // evaluation function
+	Best        *Genome        // best performing genome
+	TrainingSet []Sample       // training samples used by LamarckianEvaluation when Config.LamarckianEpochs > 0
 
 	nextGenomeID  int // genome ID that is assigned to a newly created genome
 	nextSpeciesID int // species ID that is assigned to a newly created species
+	nextNodeID    int // node ID that is assigned to a newly created node gene
+
+	nextInnovation int            // innovation number that is assigned to a newly created connection gene
+	innovations    map[[2]int]int // (from, to) -> innovation number, for structural mutations made this generation
+
+	// distanceThreshold is this NEAT instance's own compatibility distance
+	// threshold, seeded from Config.DistanceThreshold and adapted by
+	// adjustDistanceThreshold from then on. It deliberately lives here
+	// rather than on Config: IslandNEAT hands the same *Config to every
+	// island and runs them in parallel goroutines, so a shared, mutated
+	// Config.DistanceThreshold would be a data race and would also make
+	// islands adapt in lock-step instead of independently.
+	distanceThreshold float64
+
+	// rngSeed and generation together make reproduction deterministic:
+	// evaluateParallel/inheritParallel run genomes and species across
+	// goroutines, so drawing from the global math/rand source would make
+	// the sequence of random numbers each one consumes depend on
+	// goroutine scheduling, not just the seed. Instead, rngFor derives an
+	// independent *rand.Rand for each species from (rngSeed, species ID,
+	// generation), so the random numbers each species draws don't depend on
+	// scheduling, and resuming from a checkpoint continues the same
+	// sequence rather than repeating it. With more than one species, the
+	// genome IDs, node IDs and innovation numbers children receive, and the
+	// order in which species' children enter the population, still depend
+	// on the order in which the species' goroutines run.
+	rngSeed    int64
+	generation int
+}
+
+// rngFor derives an independent *rand.Rand from n.rngSeed and the given
+// parts (conventionally including n.generation and, for per-species use, the
+// species ID). Two calls with the same parts always return rngs with the
+// same seed, and no two rngs handed to concurrently running goroutines ever
+// share a seed, so callers can draw from the result without synchronization.
+func (n *NEAT) rngFor(parts ...int64) *rand.Rand {
+	seed := uint64(n.rngSeed)
+	for _, p := range parts {
+		seed ^= uint64(p) + 0x9e3779b97f4a7c15 + (seed << 6) + (seed >> 2)
+	}
+	// splitmix64 finalizer, to spread the bits of nearby seeds before
+	// they're fed to rand.NewSource.
+	seed ^= seed >> 30
+	seed *= 0xbf58476d1ce4e5b9
+	seed ^= seed >> 27
+	seed *= 0x94d049bb133111eb
+	seed ^= seed >> 31
+	return rand.New(rand.NewSource(int64(seed)))
 }
 
 // New creates a new instance of NEAT with provided argument configuration and
@@ -114,19 +174,47 @@ func New(config *Config, evaluation EvaluationFunc) *NEAT {
 		nextGenomeID++
 	}
 
+	n := &NEAT{
+		Config:            config,
+		Population:        population,
+		Evaluation:        evaluation,
+		nextGenomeID:      nextGenomeID,
+		nextNodeID:        config.NumInputs + config.NumOutputs,
+		nextInnovation:    config.NumInputs * config.NumOutputs,
+		innovations:       make(map[[2]int]int),
+		distanceThreshold: config.DistanceThreshold,
+		rngSeed:           rand.Int63(),
+	}
+
 	// initialize the first species with a randomly selected genome
-	s := NewSpecies(nextSpeciesID, population[rand.Intn(len(population))])
-	species := []*Species{s}
-	nextSpeciesID++
+	s := NewSpecies(nextSpeciesID, population[n.rngFor(0).Intn(len(population))])
+	n.Species = []*Species{s}
+	n.nextSpeciesID = nextSpeciesID + 1
 
-	return &NEAT{
-		Config:        config,
-		Population:    population,
-		Species:       species,
-		Evaluation:    evaluation,
-		nextGenomeID:  nextGenomeID,
-		nextSpeciesID: nextSpeciesID,
+	return n
+}
+
+// innovationOf returns the innovation number for a structural mutation that
+// connects the given pair of nodes, allocating a fresh one if this exact
+// mutation hasn't been seen yet this generation. Reusing innovation numbers
+// for identical structural mutations within a generation keeps genomes that
+// evolved the same structure independently comparable by gene alignment.
+func (n *NEAT) innovationOf(from, to int) int {
+	key := [2]int{from, to}
+	if innovation, ok := n.innovations[key]; ok {
+		return innovation
 	}
+	innovation := n.nextInnovation
+	n.nextInnovation++
+	n.innovations[key] = innovation
+	return innovation
+}
+
+// newNodeID returns a fresh, unique node ID.
+func (n *NEAT) newNodeID() int {
+	id := n.nextNodeID
+	n.nextNodeID++
+	return id
 }
 
 // evaluateParallel evaluates all genomes in the population in parallel.
@@ -137,18 +225,49 @@ func (n *NEAT) evaluateParallel() {
 	wg.Add(n.Config.PopulationSize)
 
 	for _, genome := range n.Population {
-		go func(genome *Genome, evalfn EvaluationFunc) {
+		go func(genome *Genome) {
 			defer wg.Done()
-			genome.Evaluate(evalfn)
-		}(genome, n.Evaluation)
+			if n.Config.LamarckianEpochs > 0 {
+				genome.Fitness = n.LamarckianEvaluation(genome)
+				return
+			}
+			genome.Evaluate(n.Evaluation)
+		}(genome)
 		time.Sleep(time.Millisecond)
 	}
 
 	wg.Wait()
 }
 
+// fitnessOf returns a fitness value to use for selection and sharing
+// purposes, where a larger value always means "more fit", regardless of
+// whether the configuration minimizes or maximizes fitness.
+func (n *NEAT) fitnessOf(genome *Genome) float64 {
+	if n.Config.MinimizeFitness {
+		return 1.0 / (1.0 + genome.Fitness)
+	}
+	return genome.Fitness
+}
+
+// selectParent picks a member of members using fitness-proportional
+// stochastic-acceptance roulette selection: repeatedly draw an index
+// uniformly at random and accept it with probability fitness[i]/fitnessMax,
+// resampling on rejection. This costs O(1) expected time per draw, unlike
+// the usual O(N) cumulative-sum roulette wheel.
+func (n *NEAT) selectParent(rng *rand.Rand, members []*Genome, fitnessMax float64) *Genome {
+	if fitnessMax <= 0 {
+		return members[rng.Intn(len(members))]
+	}
+	for {
+		i := rng.Intn(len(members))
+		if rng.Float64() < n.fitnessOf(members[i])/fitnessMax {
+			return members[i]
+		}
+	}
+}
+
 // inheritParallel performs crossover and mutation within all species in
-// parallel.
+// parallel, producing the next generation's population.
 func (n *NEAT) inheritParallel() {
 	runtime.GOMAXPROCS(len(n.Species))
 
@@ -160,47 +279,174 @@ func (n *NEAT) inheritParallel() {
 		population []*Genome // children genome for the next generation
 	}{population: make([]*Genome, 0, n.Config.PopulationSize)}
 
-	for _, species := range n.Species {
-		go func(s *Species) {
-			// genomes in this species can inherit to the next generation, if two or
-			// more genomes survive in this species.
-			survived := math.Ceil(float64(len(s.Members)) * n.Config.SurvivalRate)
+	// offspring quota for each species, proportional to its adjusted
+	// (fitness-shared) fitness relative to the whole population.
+	totalAdjusted := 0.0
+	adjusted := make([]float64, len(n.Species))
+	for i, s := range n.Species {
+		for _, genome := range s.Members {
+			adjusted[i] += n.fitnessOf(genome) / float64(len(s.Members))
+		}
+		totalAdjusted += adjusted[i]
+	}
+
+	for i, species := range n.Species {
+		quota := n.Config.PopulationSize / len(n.Species)
+		if totalAdjusted > 0 {
+			quota = int(math.Round(adjusted[i] / totalAdjusted * float64(n.Config.PopulationSize)))
+		}
+
+		go func(s *Species, quota int) {
+			defer wg.Done()
 
-			if survived > 2 {
-				// determine the method of fitness comparison, and sort the members
-				// based on their fitness.
-				comparisonFunc := func(i, j int) bool {
+			if len(s.Members) == 0 || quota <= 0 {
+				return
+			}
+
+			// this species' own independent rng stream, so it never races
+			// with another species' goroutine over shared state.
+			rng := n.rngFor(int64(s.ID), int64(n.generation))
+
+			// determine the method of fitness comparison, and sort the members
+			// from fittest to least fit.
+			comparisonFunc := func(i, j int) bool {
+				return s.Members[i].Fitness > s.Members[j].Fitness
+			}
+			if n.Config.MinimizeFitness {
+				comparisonFunc = func(i, j int) bool {
 					return s.Members[i].Fitness < s.Members[j].Fitness
 				}
-				if !n.Config.MinimizeFitness {
-					comparisonFunc = func(i, j int) bool {
-						return s.Members[i].Fitness > s.Members[j].Fitness
+			}
+			sort.Slice(s.Members, comparisonFunc)
+
+			// cull to the top SurvivalRate fraction; always keep at least the
+			// species champion around to breed from.
+			survived := int(math.Ceil(float64(len(s.Members)) * n.Config.SurvivalRate))
+			if survived < 1 {
+				survived = 1
+			}
+			if survived > len(s.Members) {
+				survived = len(s.Members)
+			}
+			parents := s.Members[:survived]
+
+			fitnessMax := 0.0
+			for _, genome := range parents {
+				if f := n.fitnessOf(genome); f > fitnessMax {
+					fitnessMax = f
+				}
+			}
+
+			children := make([]*Genome, 0, quota)
+
+			// elitism: the species champion survives unchanged into the next
+			// generation, if the species is large enough to afford it.
+			if len(s.Members) > 5 {
+				children = append(children, s.Members[0])
+			}
+
+			for len(children) < quota {
+				p1 := n.selectParent(rng, parents, fitnessMax)
+				p2 := p1
+				if len(parents) > 1 {
+					for p2 == p1 {
+						p2 = n.selectParent(rng, parents, fitnessMax)
 					}
 				}
-				sort.Slice(s.Members, comparisonFunc)
 
+				// order parents so that p1 is the fitter (or equally fit) one,
+				// since crossover takes disjoint/excess genes from it.
+				if n.fitnessOf(p2) > n.fitnessOf(p1) {
+					p1, p2 = p2, p1
+				}
+
+				nextGeneration.Lock()
+				childID := n.nextGenomeID
+				n.nextGenomeID++
+				nextGeneration.Unlock()
+
+				child := Crossover(p1, p2, childID, rng)
+
+				if rng.Float64() < n.Config.RatePerturb {
+					child.MutatePerturb(rng)
+				}
+				if rng.Float64() < n.Config.RateAddNode {
+					nextGeneration.Lock()
+					child.MutateAddNode(rng, n.newNodeID, n.innovationOf)
+					nextGeneration.Unlock()
+				}
+				if rng.Float64() < n.Config.RateAddConn {
+					nextGeneration.Lock()
+					child.MutateAddConn(rng, n.innovationOf)
+					nextGeneration.Unlock()
+				}
+
+				children = append(children, child)
 			}
-		}(species)
+
+			nextGeneration.Lock()
+			nextGeneration.population = append(nextGeneration.population, children...)
+			nextGeneration.Unlock()
+		}(species, quota)
 	}
 
 	wg.Wait()
 
-	// update the population with the new generation
+	// top up or trim so the population size stays exactly constant, in case
+	// rounding of per-species quotas left it short or over. Runs after
+	// wg.Wait(), so it's safe to use a single rng here rather than one per
+	// species; -1 can't collide with a species ID, which are always >= 0.
+	mainRng := n.rngFor(-1, int64(n.generation))
+	for len(nextGeneration.population) < n.Config.PopulationSize {
+		s := n.Species[mainRng.Intn(len(n.Species))]
+		if len(s.Members) == 0 {
+			continue
+		}
+		clone := Crossover(s.Members[0], s.Members[0], n.nextGenomeID, mainRng)
+		n.nextGenomeID++
+		nextGeneration.population = append(nextGeneration.population, clone)
+	}
+	if len(nextGeneration.population) > n.Config.PopulationSize {
+		nextGeneration.population = nextGeneration.population[:n.Config.PopulationSize]
+	}
+
+	// update the population with the new generation, and reset per-generation
+	// bookkeeping that must not leak into the next one.
 	n.Population = nextGeneration.population
+	n.innovations = make(map[[2]int]int)
 }
 
-// Run executes evolution.
+// Run executes evolution for Config.NumGenerations generations.
 func (n *NEAT) Run() {
-	for i := 0; i < n.Config.NumGenerations; i++ {
+	n.RunFor(n.Config.NumGenerations)
+}
+
+// RunFor executes evolution for the given number of generations, regardless
+// of Config.NumGenerations. It's meant to be called repeatedly by callers
+// that checkpoint the NEAT instance every K generations with SaveCheckpoint,
+// rather than running the whole evolution in one Run call. Each generation's
+// reproduction draws from rngs seeded off n.generation (see rngFor), so
+// splitting a run across several RunFor calls — with or without a
+// checkpoint save/reload in between — draws the same random numbers as one
+// RunFor call covering the same total (see rngSeed for what can still vary
+// with more than one species).
+func (n *NEAT) RunFor(generations int) {
+	for i := 0; i < generations; i++ {
 		n.evaluateParallel()
 
+		// each species keeps its representative but starts this generation's
+		// membership list fresh.
+		for _, s := range n.Species {
+			s.Members = s.Members[:0]
+		}
+
 		for _, genome := range n.Population {
 			registered := false
 			for i := 0; i < len(n.Species) && !registered; i++ {
 				dist := Compatibility(n.Species[i].Representative, genome,
 					n.Config.CoeffUnmatching, n.Config.CoeffMatching)
 
-				if dist < n.Config.DistanceThreshold {
+				if dist < n.distanceThreshold {
 					n.Species[i].Register(genome, n.Config.MinimizeFitness)
 					registered = true
 				}
@@ -210,8 +456,62 @@ func (n *NEAT) Run() {
 				n.Species = append(n.Species, NewSpecies(n.nextSpeciesID, genome))
 				n.nextSpeciesID++
 			}
+
+			if n.Best == nil || (n.Config.MinimizeFitness && genome.Fitness < n.Best.Fitness) ||
+				(!n.Config.MinimizeFitness && genome.Fitness > n.Best.Fitness) {
+				n.Best = genome
+			}
+		}
+
+		n.pruneExtinctSpecies()
+		n.adjustDistanceThreshold()
+		n.inheritParallel()
+		n.generation++
+	}
+}
+
+// pruneExtinctSpecies drops species that registered no members this
+// generation, so that the species count used by adjustDistanceThreshold
+// reflects only species that are actually still alive.
+func (n *NEAT) pruneExtinctSpecies() {
+	alive := n.Species[:0]
+	for _, s := range n.Species {
+		if len(s.Members) > 0 {
+			alive = append(alive, s)
 		}
+	}
+	n.Species = alive
+}
+
+// distanceThresholdStep is how much adjustDistanceThreshold nudges
+// distanceThreshold per generation to steer the species count towards
+// Config.TargetSpecies.
+const distanceThresholdStep = 0.1
 
-		//n.inheritParallel()
+// minDistanceThreshold is a floor on distanceThreshold so adaptation never
+// collapses it to zero or below, which would force every genome into its
+// own species.
+const minDistanceThreshold = 0.1
+
+// adjustDistanceThreshold nudges this instance's distanceThreshold up when
+// there are more species than Config.TargetSpecies, and down when there are
+// fewer, keeping the species count near the target without needing to be
+// tuned by hand. It's a no-op when Config.TargetSpecies is 0. Each NEAT
+// keeps its own distanceThreshold rather than sharing Config.DistanceThreshold,
+// so that islands under IslandNEAT (which share one *Config) adapt
+// independently instead of racing on the same field.
+func (n *NEAT) adjustDistanceThreshold() {
+	if n.Config.TargetSpecies <= 0 {
+		return
+	}
+
+	switch {
+	case len(n.Species) > n.Config.TargetSpecies:
+		n.distanceThreshold += distanceThresholdStep
+	case len(n.Species) < n.Config.TargetSpecies:
+		n.distanceThreshold -= distanceThresholdStep
+		if n.distanceThreshold < minDistanceThreshold {
+			n.distanceThreshold = minDistanceThreshold
+		}
 	}
 }
@@ -2,6 +2,7 @@ package neat
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 )
@@ -25,4 +26,150 @@ func NEATUnitTest() {
 func TestNEAT(t *testing.T) {
 	rand.Seed(0)
 	NEATUnitTest()
+}
+
+// xorFitness scores a network against the four XOR input/output pairs; a
+// perfect network scores 4.0.
+func xorFitness(nn *NeuralNetwork) float64 {
+	samples := []struct {
+		inputs []float64
+		target float64
+	}{
+		{[]float64{0, 0}, 0},
+		{[]float64{0, 1}, 1},
+		{[]float64{1, 0}, 1},
+		{[]float64{1, 1}, 0},
+	}
+
+	fitness := 0.0
+	for _, sample := range samples {
+		outputs, err := nn.FeedForward(sample.inputs)
+		if err != nil {
+			continue
+		}
+		fitness += 1.0 - math.Abs(outputs[0]-sample.target)
+	}
+	return fitness
+}
+
+func TestInheritKeepsPopulationSizeConstant(t *testing.T) {
+	rand.Seed(1)
+
+	config := &Config{
+		NumInputs:         2,
+		NumOutputs:        1,
+		NumGenerations:    5,
+		PopulationSize:    30,
+		SurvivalRate:      0.5,
+		RatePerturb:       0.8,
+		RateAddNode:       0.05,
+		RateAddConn:       0.1,
+		DistanceThreshold: 3.0,
+		CoeffUnmatching:   1.0,
+		CoeffMatching:     0.4,
+	}
+
+	n := New(config, xorFitness)
+
+	for i := 0; i < config.NumGenerations; i++ {
+		n.evaluateParallel()
+
+		for _, s := range n.Species {
+			s.Members = s.Members[:0]
+		}
+		for _, genome := range n.Population {
+			registered := false
+			for j := 0; j < len(n.Species) && !registered; j++ {
+				dist := Compatibility(n.Species[j].Representative, genome,
+					config.CoeffUnmatching, config.CoeffMatching)
+				if dist < config.DistanceThreshold {
+					n.Species[j].Register(genome, config.MinimizeFitness)
+					registered = true
+				}
+			}
+			if !registered {
+				n.Species = append(n.Species, NewSpecies(n.nextSpeciesID, genome))
+				n.nextSpeciesID++
+			}
+		}
+
+		n.inheritParallel()
+
+		if len(n.Population) != config.PopulationSize {
+			t.Fatalf("generation %d: population size = %d, want %d", i, len(n.Population), config.PopulationSize)
+		}
+	}
+}
+
+func TestRunImprovesMeanFitnessOnXOR(t *testing.T) {
+	rand.Seed(2)
+
+	config := &Config{
+		NumInputs:         2,
+		NumOutputs:        1,
+		NumGenerations:    20,
+		PopulationSize:    50,
+		SurvivalRate:      0.4,
+		RatePerturb:       0.8,
+		RateAddNode:       0.03,
+		RateAddConn:       0.1,
+		DistanceThreshold: 3.0,
+		CoeffUnmatching:   1.0,
+		CoeffMatching:     0.4,
+	}
+
+	meanFitness := func(n *NEAT) float64 {
+		sum := 0.0
+		for _, genome := range n.Population {
+			sum += genome.Fitness
+		}
+		return sum / float64(len(n.Population))
+	}
+
+	n := New(config, xorFitness)
+	n.evaluateParallel()
+	before := meanFitness(n)
+
+	n.Run()
+	n.evaluateParallel()
+	after := meanFitness(n)
+
+	if after <= before {
+		t.Fatalf("mean fitness did not improve: before = %.3f, after = %.3f", before, after)
+	}
+}
+
+func TestAdjustDistanceThresholdTracksTargetSpecies(t *testing.T) {
+	n := &NEAT{Config: &Config{DistanceThreshold: 3.0, TargetSpecies: 5}, distanceThreshold: 3.0}
+
+	n.Species = make([]*Species, 8)
+	n.adjustDistanceThreshold()
+	if n.distanceThreshold <= 3.0 {
+		t.Fatalf("distanceThreshold = %.3f, want > 3.0 when species count exceeds target", n.distanceThreshold)
+	}
+
+	n.distanceThreshold = 3.0
+	n.Species = make([]*Species, 2)
+	n.adjustDistanceThreshold()
+	if n.distanceThreshold >= 3.0 {
+		t.Fatalf("distanceThreshold = %.3f, want < 3.0 when species count is below target", n.distanceThreshold)
+	}
+}
+
+// TestDistanceThresholdIsPerInstance guards against regressing adaptation
+// back onto the shared *Config, which would race across islands.
+func TestDistanceThresholdIsPerInstance(t *testing.T) {
+	config := &Config{DistanceThreshold: 3.0, TargetSpecies: 1}
+	a := &NEAT{Config: config, distanceThreshold: config.DistanceThreshold}
+	b := &NEAT{Config: config, distanceThreshold: config.DistanceThreshold}
+
+	a.Species = make([]*Species, 5)
+	a.adjustDistanceThreshold()
+
+	if b.distanceThreshold != 3.0 {
+		t.Fatalf("adjusting a's distanceThreshold changed b's: got %.3f, want 3.0", b.distanceThreshold)
+	}
+	if config.DistanceThreshold != 3.0 {
+		t.Fatalf("adjustDistanceThreshold mutated the shared Config.DistanceThreshold: got %.3f, want 3.0", config.DistanceThreshold)
+	}
 }
\ No newline at end of file
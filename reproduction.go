@@ -0,0 +1,142 @@
+package neat
+
+import "math/rand"
+
+// weightPerturbStep bounds how far a single perturbation mutation can nudge
+// a connection weight.
+const weightPerturbStep = 0.5
+
+// geneDisableProbability is the probability that a gene inherited from a
+// parent where it was disabled in either parent remains disabled in the
+// offspring.
+const geneDisableProbability = 0.75
+
+// Crossover produces a child genome by aligning p1 and p2's connection genes
+// by innovation number. Matching genes are inherited from a uniformly random
+// parent; disjoint and excess genes are inherited from p1, which the caller
+// must pass as the fitter (or equally fit) parent. A gene disabled in either
+// parent has a chance of staying disabled in the child. rng is the caller's
+// own source of randomness, so that two callers drawing from independent
+// rngs (as NEAT does, one per species) never race on a shared one.
+func Crossover(p1, p2 *Genome, childID int, rng *rand.Rand) *Genome {
+	p2ByInnovation := make(map[int]*ConnGene, len(p2.ConnGenes))
+	for _, gene := range p2.ConnGenes {
+		p2ByInnovation[gene.Innovation] = gene
+	}
+
+	connGenes := make([]*ConnGene, 0, len(p1.ConnGenes))
+	for _, gene := range p1.ConnGenes {
+		chosen := gene
+		disabled := gene.Disabled
+
+		if other, matched := p2ByInnovation[gene.Innovation]; matched {
+			if rng.Intn(2) == 0 {
+				chosen = other
+			}
+			if gene.Disabled || other.Disabled {
+				disabled = rng.Float64() < geneDisableProbability
+			} else {
+				disabled = false
+			}
+		}
+
+		connGenes = append(connGenes, &ConnGene{
+			From:       chosen.From,
+			To:         chosen.To,
+			Weight:     chosen.Weight,
+			Disabled:   disabled,
+			Innovation: chosen.Innovation,
+		})
+	}
+
+	nodeGenes := make([]*NodeGene, len(p1.NodeGenes))
+	for i, node := range p1.NodeGenes {
+		copied := *node
+		nodeGenes[i] = &copied
+	}
+
+	child := NewGenome(childID, 0, 0)
+	child.NodeGenes = nodeGenes
+	child.ConnGenes = connGenes
+	return child
+}
+
+// MutatePerturb nudges every connection gene's weight by a small random
+// amount.
+func (g *Genome) MutatePerturb(rng *rand.Rand) {
+	for _, gene := range g.ConnGenes {
+		gene.Weight += (rng.Float64()*2 - 1) * weightPerturbStep
+	}
+}
+
+// MutateAddNode splits a randomly chosen enabled connection in two: the
+// original connection is disabled, a new hidden node is inserted in its
+// place, and two new connections are added around it (the one leading into
+// the new node with weight 1.0, the one leading out of it with the split
+// connection's weight; the new node's sigmoid activation still changes the
+// signal passing through). newNodeID allocates the new node's ID and
+// innovationOf allocates innovation numbers for the two new connections.
+func (g *Genome) MutateAddNode(rng *rand.Rand, newNodeID func() int, innovationOf func(from, to int) int) {
+	candidates := make([]*ConnGene, 0, len(g.ConnGenes))
+	for _, gene := range g.ConnGenes {
+		if !gene.Disabled {
+			candidates = append(candidates, gene)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	split := candidates[rng.Intn(len(candidates))]
+	split.Disabled = true
+
+	newNode := &NodeGene{
+		ID:         newNodeID(),
+		Type:       "hidden",
+		Activation: Sigmoid,
+	}
+	g.NodeGenes = append(g.NodeGenes, newNode)
+
+	g.ConnGenes = append(g.ConnGenes,
+		&ConnGene{
+			From:       split.From,
+			To:         newNode,
+			Weight:     1.0,
+			Innovation: innovationOf(split.From.ID, newNode.ID),
+		},
+		&ConnGene{
+			From:       newNode,
+			To:         split.To,
+			Weight:     split.Weight,
+			Innovation: innovationOf(newNode.ID, split.To.ID),
+		},
+	)
+}
+
+// MutateAddConn adds a new connection gene between two previously
+// unconnected nodes, with a small random weight. It is a no-op if no such
+// pair of nodes can be found after a handful of attempts.
+func (g *Genome) MutateAddConn(rng *rand.Rand, innovationOf func(from, to int) int) {
+	existing := make(map[[2]int]bool, len(g.ConnGenes))
+	for _, gene := range g.ConnGenes {
+		existing[[2]int{gene.From.ID, gene.To.ID}] = true
+	}
+
+	const attempts = 20
+	for i := 0; i < attempts; i++ {
+		from := g.NodeGenes[rng.Intn(len(g.NodeGenes))]
+		to := g.NodeGenes[rng.Intn(len(g.NodeGenes))]
+
+		if to.Type == "input" || from == to || existing[[2]int{from.ID, to.ID}] {
+			continue
+		}
+
+		g.ConnGenes = append(g.ConnGenes, &ConnGene{
+			From:       from,
+			To:         to,
+			Weight:     rng.Float64()*2 - 1,
+			Innovation: innovationOf(from.ID, to.ID),
+		})
+		return
+	}
+}